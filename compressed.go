@@ -0,0 +1,177 @@
+package hiertree
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// compressedNode is a pointer-linked intermediate representation used while building a compressed
+// tree, so a node already linked into its parent can be split in place; Node holds its Children
+// by value.
+type compressedNode struct {
+	// comps are the path components collapsed into this node, e.g. ["foo", "bar", "baz"] for a
+	// node that represents the compressed chain "foo/bar/baz".
+	comps    []string
+	elem     Elem
+	children []*compressedNode
+}
+
+// CompressedTree arranges elems into a path-compressed (radix) tree based on their hierarchical
+// paths. Any chain of stub nodes (nodes with no Elem) that has exactly one child is collapsed into
+// a single Node whose Name holds the joined path components and whose Prefix holds those
+// components individually, so callers can still walk the tree logically per-component while
+// iteration over the result is O(distinct branch points) rather than O(total path components).
+//
+// Unlike Tree, which re-scans its input once per tree level, CompressedTree sorts
+// once and then inserts each element in a single pass, descending only through the ancestors of
+// the previously inserted node (as in a Patricia trie insert) and splitting a compressed node only
+// when a new path diverges partway through it.
+func CompressedTree(elems []Elem) ([]Node, error) {
+	es := make(elemlist, len(elems))
+	copy(es, elems)
+	sort.Sort(es)
+
+	var roots, stack []*compressedNode
+	for _, e := range es {
+		comps := splitComponents(e.HierPath())
+		if len(comps) == 0 {
+			return nil, invalidPathError(e.HierPath())
+		}
+		for _, c := range comps {
+			if c == "" {
+				return nil, invalidPathError(e.HierPath())
+			}
+		}
+		var err error
+		roots, stack, err = insertCompressed(roots, stack, comps, e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buildCompressed(roots), nil
+}
+
+// insertCompressed inserts comps (with associated elem e) into roots, descending through stack
+// (the ancestor chain of the previously-inserted node) to find where comps diverges from it, and
+// returns the updated roots and the new ancestor chain ending at the node that now holds e.
+func insertCompressed(roots, stack []*compressedNode, comps []string, e Elem) ([]*compressedNode, []*compressedNode, error) {
+	pos := 0
+	for depth := 0; depth < len(stack); depth++ {
+		n := stack[depth]
+		cl := commonPrefixLen(n.comps, comps[pos:])
+
+		if cl == 0 {
+			// comps shares nothing with n; it's a new sibling of n at this depth.
+			newChild := &compressedNode{comps: comps[pos:], elem: e}
+			if depth == 0 {
+				roots = append(roots, newChild)
+			} else {
+				parent := stack[depth-1]
+				parent.children = append(parent.children, newChild)
+			}
+			return roots, appendStack(stack, depth, newChild), nil
+		}
+
+		if cl < len(n.comps) {
+			// comps diverges partway through n (or ends exactly inside it); split n in two.
+			split := &compressedNode{comps: n.comps[:cl]}
+			rest := &compressedNode{comps: n.comps[cl:], elem: n.elem, children: n.children}
+			remaining := comps[pos+cl:]
+			if len(remaining) == 0 {
+				split.elem = e
+				split.children = []*compressedNode{rest}
+				*n = *split
+				return roots, appendStack(stack, depth, n), nil
+			}
+			newChild := &compressedNode{comps: remaining, elem: e}
+			split.children = []*compressedNode{rest, newChild}
+			*n = *split
+			return roots, appendStack(stack, depth, n, newChild), nil
+		}
+
+		// cl == len(n.comps): comps fully covers n, so descend into its children.
+		pos += len(n.comps)
+	}
+
+	// The entire existing stack (if any) is a prefix of comps.
+	if len(stack) == 0 {
+		newChild := &compressedNode{comps: comps, elem: e}
+		roots = append(roots, newChild)
+		return roots, []*compressedNode{newChild}, nil
+	}
+	leaf := stack[len(stack)-1]
+	if pos == len(comps) {
+		if leaf.elem != nil {
+			return nil, nil, duplicatePathError(comps)
+		}
+		leaf.elem = e
+		return roots, stack, nil
+	}
+	newChild := &compressedNode{comps: comps[pos:], elem: e}
+	leaf.children = append(leaf.children, newChild)
+	return roots, appendStack(stack, len(stack), newChild), nil
+}
+
+// appendStack returns stack truncated to depth with extra appended, without mutating stack's
+// underlying array.
+func appendStack(stack []*compressedNode, depth int, extra ...*compressedNode) []*compressedNode {
+	out := make([]*compressedNode, depth, depth+len(extra))
+	copy(out, stack[:depth])
+	return append(out, extra...)
+}
+
+// commonPrefixLen returns the number of leading elements shared by a and b.
+func commonPrefixLen(a, b []string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var i int
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func buildCompressed(nodes []*compressedNode) []Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	out := make([]Node, len(nodes))
+	for i, n := range nodes {
+		out[i] = Node{
+			Name:     joinComponents(n.comps),
+			Elem:     n.elem,
+			Children: buildCompressed(n.children),
+			Prefix:   n.comps,
+		}
+	}
+	return out
+}
+
+func splitComponents(path string) []string {
+	var comps []string
+	for path != "" {
+		root, rest := split(path)
+		comps = append(comps, root)
+		path = rest
+	}
+	return comps
+}
+
+func joinComponents(comps []string) string {
+	s := comps[0]
+	for _, c := range comps[1:] {
+		s += "/" + c
+	}
+	return s
+}
+
+func invalidPathError(path string) error {
+	return errors.New("invalid node path: " + path)
+}
+
+func duplicatePathError(comps []string) error {
+	return errors.New("duplicate node path: " + strings.Join(comps, "/"))
+}
@@ -0,0 +1,189 @@
+package hiertree
+
+// ChangeKind describes how an entry differs between the two path sets passed to Diff.
+type ChangeKind int
+
+const (
+	// Unchanged indicates the entry is present and identical on both sides.
+	Unchanged ChangeKind = iota
+	// Added indicates the entry is only present in the new path set.
+	Added
+	// Removed indicates the entry is only present in the old path set.
+	Removed
+	// Modified indicates the entry (or one of its descendants) differs between the two path sets.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Unchanged:
+		return "unchanged"
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return "invalid"
+	}
+}
+
+// Equaler is an optional interface that Elem implementations can satisfy to customize how Diff
+// decides whether an element changed. If an Elem does not implement Equaler, Diff falls back to
+// comparing the two Elem values with ==.
+type Equaler interface {
+	// Equal reports whether other represents the same value as this Elem.
+	Equal(other Elem) bool
+}
+
+// Change describes a single entry in the result of Diff.
+type Change struct {
+	// Parent is the full path of this entry's parent.
+	Parent string
+
+	// Name is the name of this entry (without the parent path).
+	Name string
+
+	// Old is the element that existed at this position in a, or nil if the entry was added or is
+	// a stub.
+	Old Elem
+
+	// New is the element that exists at this position in b, or nil if the entry was removed or is
+	// a stub.
+	New Elem
+
+	// Kind describes how the entry changed between a and b.
+	Kind ChangeKind
+
+	// Leaf is true iff this entry has no children (on whichever side still contains it).
+	Leaf bool
+}
+
+// Diff compares two path sets, a and b, and returns the differences organized in the same
+// hierarchical layout that List and Tree use. Stub entries (directories with no Elem on either
+// side) are only included when one of their descendants changed, so that unrelated stub ancestors
+// don't clutter the result.
+func Diff(a, b []Elem) ([]Change, error) {
+	oldNodes, err := Tree(a)
+	if err != nil {
+		return nil, err
+	}
+	newNodes, err := Tree(b)
+	if err != nil {
+		return nil, err
+	}
+	changes, _ := diffNodes(oldNodes, newNodes, "")
+	return changes, nil
+}
+
+// diffNodes merges two (already name-sorted) sibling node lists and returns the resulting changes
+// along with whether anything changed within them.
+func diffNodes(oldNodes, newNodes []Node, parent string) (changes []Change, anyChanged bool) {
+	var i, j int
+	for i < len(oldNodes) || j < len(newNodes) {
+		switch {
+		case j >= len(newNodes) || (i < len(oldNodes) && oldNodes[i].Name < newNodes[j].Name):
+			changes = append(changes, diffRemoved(oldNodes[i], parent)...)
+			anyChanged = true
+			i++
+		case i >= len(oldNodes) || newNodes[j].Name < oldNodes[i].Name:
+			changes = append(changes, diffAdded(newNodes[j], parent)...)
+			anyChanged = true
+			j++
+		default:
+			c, changed := diffNode(oldNodes[i], newNodes[j], parent)
+			changes = append(changes, c...)
+			anyChanged = anyChanged || changed
+			i++
+			j++
+		}
+	}
+	return
+}
+
+// diffNode diffs a single matched pair of old/new nodes (same Name, same Parent).
+func diffNode(o, n Node, parent string) (changes []Change, changed bool) {
+	childPrefix := join(parent, o.Name)
+	childChanges, childrenChanged := diffNodes(o.Children, n.Children, childPrefix)
+
+	stub := o.Elem == nil && n.Elem == nil
+	elemChanged := !elemEqual(o.Elem, n.Elem)
+	changed = elemChanged || childrenChanged
+
+	if stub && !changed {
+		// Nothing changed under this stub; omit it entirely.
+		return childChanges, false
+	}
+
+	kind := Unchanged
+	if elemChanged {
+		kind = Modified
+	} else if childrenChanged {
+		kind = Modified
+	}
+
+	change := Change{
+		Parent: parent,
+		Name:   o.Name,
+		Old:    o.Elem,
+		New:    n.Elem,
+		Kind:   kind,
+		Leaf:   len(n.Children) == 0,
+	}
+	changes = append(changes, change)
+	changes = append(changes, childChanges...)
+	return changes, changed
+}
+
+// diffAdded emits Added changes for n and all of its descendants.
+func diffAdded(n Node, parent string) (changes []Change) {
+	changes = append(changes, Change{
+		Parent: parent,
+		Name:   n.Name,
+		New:    n.Elem,
+		Kind:   Added,
+		Leaf:   len(n.Children) == 0,
+	})
+	childPrefix := join(parent, n.Name)
+	for _, c := range n.Children {
+		changes = append(changes, diffAdded(c, childPrefix)...)
+	}
+	return
+}
+
+// diffRemoved emits Removed changes for o and all of its descendants.
+func diffRemoved(o Node, parent string) (changes []Change) {
+	changes = append(changes, Change{
+		Parent: parent,
+		Name:   o.Name,
+		Old:    o.Elem,
+		Kind:   Removed,
+		Leaf:   len(o.Children) == 0,
+	})
+	childPrefix := join(parent, o.Name)
+	for _, c := range o.Children {
+		changes = append(changes, diffRemoved(c, childPrefix)...)
+	}
+	return
+}
+
+// elemEqual reports whether two Elem values (either of which may be nil) should be considered
+// equal for the purposes of Diff.
+func elemEqual(a, b Elem) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if ea, ok := a.(Equaler); ok {
+		return ea.Equal(b)
+	}
+	return a == b
+}
+
+// join joins a parent path and a name, matching the path layout used by List.
+func join(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
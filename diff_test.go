@@ -0,0 +1,111 @@
+package hiertree
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func inspectChanges(changes []Change) (out []string) {
+	out = make([]string, len(changes))
+	for i, c := range changes {
+		s := c.Kind.String() + ":"
+		if c.Parent != "" {
+			s += "[" + c.Parent + "/]"
+		}
+		s += c.Name
+		if c.Leaf {
+			s += "*"
+		}
+		out[i] = s
+	}
+	return
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b  []Elem
+		want  []string
+		error string
+	}{
+		{
+			a:    mkElems([]elem{}),
+			b:    mkElems([]elem{}),
+			want: []string{},
+		},
+		{
+			a:    mkElems([]elem{}),
+			b:    mkElems([]elem{"foo"}),
+			want: []string{"added:foo*"},
+		},
+		{
+			a:    mkElems([]elem{"foo"}),
+			b:    mkElems([]elem{}),
+			want: []string{"removed:foo*"},
+		},
+		{
+			a:    mkElems([]elem{"foo"}),
+			b:    mkElems([]elem{"foo"}),
+			want: []string{"unchanged:foo*"},
+		},
+		{
+			a:    mkElems([]elem{"foo"}),
+			b:    mkElems([]elem{"bar"}),
+			want: []string{"added:bar*", "removed:foo*"},
+		},
+		{
+			a:    mkElems([]elem{"foo/bar"}),
+			b:    mkElems([]elem{"foo/bar", "foo/baz"}),
+			want: []string{"modified:foo", "unchanged:[foo/]bar*", "added:[foo/]baz*"},
+		},
+		{
+			a:    mkElems([]elem{"foo/bar", "foo/baz"}),
+			b:    mkElems([]elem{"foo/bar"}),
+			want: []string{"modified:foo", "unchanged:[foo/]bar*", "removed:[foo/]baz*"},
+		},
+		{
+			a:    mkElems([]elem{"foo/bar"}),
+			b:    mkElems([]elem{"baz/bar"}),
+			want: []string{"added:baz", "added:[baz/]bar*", "removed:foo", "removed:[foo/]bar*"},
+		},
+	}
+
+	for i, test := range tests {
+		label := fmt.Sprintf("test#%d a=%v b=%v:", i, test.a, test.b)
+		changes, err := Diff(test.a, test.b)
+		if test.error == "" {
+			if err != nil {
+				t.Errorf("%s unexpected error %q", label, err)
+				continue
+			}
+			if got := inspectChanges(changes); !reflect.DeepEqual(test.want, got) {
+				t.Errorf("%s\nwant %v\n got %v", label, test.want, got)
+			}
+		} else if err == nil {
+			t.Errorf("%s want error containing %q, got no error", label, test.error)
+		}
+	}
+}
+
+type versionedElem struct {
+	elem
+	version int
+}
+
+func (v versionedElem) Equal(other Elem) bool {
+	o, ok := other.(versionedElem)
+	return ok && v.elem == o.elem && v.version == o.version
+}
+
+func TestDiffEqualer(t *testing.T) {
+	a := []Elem{versionedElem{"foo", 1}}
+	b := []Elem{versionedElem{"foo", 2}}
+
+	changes, err := Diff(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if want := []string{"modified:foo*"}; !reflect.DeepEqual(want, inspectChanges(changes)) {
+		t.Errorf("want %v, got %v", want, inspectChanges(changes))
+	}
+}
@@ -61,6 +61,22 @@ func list(nodes []Node, parent string) (entries []Entry, err error) {
 	return
 }
 
+// Inspect returns a list of path strings of the form "[parent/]name*", where the asterisk
+// indicates that the entry is not a stub. It's meant for use in tests and debugging.
+func Inspect(entries []Entry) (paths []string) {
+	paths = make([]string, len(entries))
+	for i, e := range entries {
+		if e.Parent != "" {
+			paths[i] += "[" + e.Parent + "/]"
+		}
+		paths[i] += e.Name
+		if e.Elem != nil {
+			paths[i] += "*"
+		}
+	}
+	return
+}
+
 // Node represents a node in the resulting tree. Elem is nil if the entry is a stub (i.e., no
 // element exists at this path, but it does contain elements).
 type Node struct {
@@ -72,6 +88,11 @@ type Node struct {
 
 	// Children is the list of child nodes under this node
 	Children []Node
+
+	// Prefix holds the path components collapsed into this node by CompressedTree, in order
+	// (e.g. ["foo", "bar", "baz"] for a Name of "foo/bar/baz"). It is nil for trees produced by
+	// Tree, since those nodes are never compressed.
+	Prefix []string
 }
 
 // Tree arranges elems into a tree based on their hierarchical paths.
@@ -80,7 +101,7 @@ func Tree(elems []Elem) (nodes []Node, err error) {
 	return
 }
 
-func tree(elems []Elem, prefix string) (roots []Node, size int, err error) {
+func tree(elems []Elem, prefix string) (roots []Node, consumed int, err error) {
 	var err2 error
 	es := elemlist(elems)
 	if prefix == "" { // only sort on first call
@@ -89,17 +110,17 @@ func tree(elems []Elem, prefix string) (roots []Node, size int, err error) {
 	var cur *Node
 	var saveCur = func() {
 		if cur != nil {
-			size++
 			roots = append(roots, *cur)
 		}
 		cur = nil
 	}
 	defer saveCur()
-	for i := 0; i < len(es); i++ {
+	var i int
+	for i = 0; i < len(es); i++ {
 		e := es[i]
 		path := e.HierPath()
 		if !strings.HasPrefix(path, prefix) {
-			return
+			break
 		}
 		relpath := path[len(prefix):]
 		root, rest := split(relpath)
@@ -116,16 +137,20 @@ func tree(elems []Elem, prefix string) (roots []Node, size int, err error) {
 		if rest == "" {
 			cur.Elem = e
 		}
+		// n is the number of elements of es consumed by the child subtree (i.e. how many of
+		// them share the prefix+root+"/" prefix), which may be more than one when root has
+		// its own children. It must not be confused with the number of Nodes produced, since
+		// stub nodes don't consume an element of their own.
 		var n int
 		cur.Children, n, err2 = tree(elems[i:], prefix+root+"/")
 		if err2 != nil && err == nil {
 			err = err2
 		}
-		size += n
 		if n > 0 {
 			i += n - 1
 		}
 	}
+	consumed = i
 	return
 }
 
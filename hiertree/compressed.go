@@ -0,0 +1,157 @@
+package hiertree
+
+import (
+	"fmt"
+	"sort"
+)
+
+// compressedNode is a pointer-linked intermediate representation used while building a compressed
+// tree, so a node already linked into its parent can be split in place; Node holds its Children
+// by value.
+type compressedNode struct {
+	// comps are the path components collapsed into this node, e.g. ["foo", "bar", "baz"] for a
+	// node that represents the compressed chain foo/bar/baz.
+	comps    []string
+	elem     Elem
+	children []*compressedNode
+}
+
+// CompressedTree arranges elems into a path-compressed (radix) tree based on their hierarchical
+// paths. Any chain of stub nodes (nodes with no Elem) that has exactly one child is collapsed into
+// a single Node whose Name holds the joined path components and whose Prefix holds those
+// components individually (e.g. ["foo", "bar", "baz"] for a Name of "foo/bar/baz"), so callers can
+// still walk the tree logically per-component while iteration over the result is O(distinct branch
+// points) rather than O(total path components).
+//
+// Unlike Tree, which re-scans its input once per tree level, CompressedTree sorts
+// once and then inserts each element in a single pass, descending only through the ancestors of
+// the previously inserted node (as in a Patricia trie insert) and splitting a compressed node only
+// when a new path diverges partway through it.
+func CompressedTree(elems []Elem) ([]Node, error) {
+	es := elemlist(append([]Elem{}, elems...))
+	sort.Sort(es)
+
+	var roots, stack []*compressedNode
+	for _, e := range es {
+		comps := e.PathComponents()
+		if len(comps) == 0 {
+			return nil, invalidPathErr(comps)
+		}
+		for _, c := range comps {
+			if c == "" {
+				return nil, invalidPathErr(comps)
+			}
+		}
+		var err error
+		roots, stack, err = insertCompressed(roots, stack, comps, e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buildCompressed(roots), nil
+}
+
+func insertCompressed(roots, stack []*compressedNode, comps []string, e Elem) ([]*compressedNode, []*compressedNode, error) {
+	pos := 0
+	for depth := 0; depth < len(stack); depth++ {
+		n := stack[depth]
+		cl := commonPrefixLen(n.comps, comps[pos:])
+
+		if cl == 0 {
+			newChild := &compressedNode{comps: comps[pos:], elem: e}
+			if depth == 0 {
+				roots = append(roots, newChild)
+			} else {
+				stack[depth-1].children = append(stack[depth-1].children, newChild)
+			}
+			return roots, appendStack(stack, depth, newChild), nil
+		}
+
+		if cl < len(n.comps) {
+			split := &compressedNode{comps: n.comps[:cl]}
+			rest := &compressedNode{comps: n.comps[cl:], elem: n.elem, children: n.children}
+			remaining := comps[pos+cl:]
+			if len(remaining) == 0 {
+				split.elem = e
+				split.children = []*compressedNode{rest}
+				*n = *split
+				return roots, appendStack(stack, depth, n), nil
+			}
+			newChild := &compressedNode{comps: remaining, elem: e}
+			split.children = []*compressedNode{rest, newChild}
+			*n = *split
+			return roots, appendStack(stack, depth, n, newChild), nil
+		}
+
+		pos += len(n.comps)
+	}
+
+	if len(stack) == 0 {
+		newChild := &compressedNode{comps: comps, elem: e}
+		roots = append(roots, newChild)
+		return roots, []*compressedNode{newChild}, nil
+	}
+	leaf := stack[len(stack)-1]
+	if pos == len(comps) {
+		if leaf.elem != nil {
+			return nil, nil, duplicatePathErr(comps)
+		}
+		leaf.elem = e
+		return roots, stack, nil
+	}
+	newChild := &compressedNode{comps: comps[pos:], elem: e}
+	leaf.children = append(leaf.children, newChild)
+	return roots, appendStack(stack, len(stack), newChild), nil
+}
+
+// appendStack returns stack truncated to depth with extra appended, without mutating stack's
+// underlying array.
+func appendStack(stack []*compressedNode, depth int, extra ...*compressedNode) []*compressedNode {
+	out := make([]*compressedNode, depth, depth+len(extra))
+	copy(out, stack[:depth])
+	return append(out, extra...)
+}
+
+func commonPrefixLen(a, b []string) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	var i int
+	for i < n && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func buildCompressed(nodes []*compressedNode) []Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	out := make([]Node, len(nodes))
+	for i, n := range nodes {
+		out[i] = Node{
+			Name:     joinComponents(n.comps),
+			Elem:     n.elem,
+			Children: buildCompressed(n.children),
+			Prefix:   n.comps,
+		}
+	}
+	return out
+}
+
+func joinComponents(comps []string) string {
+	s := comps[0]
+	for _, c := range comps[1:] {
+		s += "/" + c
+	}
+	return s
+}
+
+func invalidPathErr(comps []string) error {
+	return fmt.Errorf("invalid node path: %q", comps)
+}
+
+func duplicatePathErr(comps []string) error {
+	return fmt.Errorf("duplicate node path: %q", comps)
+}
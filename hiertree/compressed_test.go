@@ -0,0 +1,152 @@
+package hiertree
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type elem string
+
+func (e elem) PathComponents() []string {
+	return strings.Split(string(e), "/")
+}
+
+func mkElems(in []elem) (out []Elem) {
+	out = make([]Elem, len(in))
+	for i, e := range in {
+		out[i] = Elem(e)
+	}
+	return
+}
+
+// inspectCompressed renders a compressed tree as "[parent/]name*" strings, where the asterisk
+// marks a non-stub; the compressed Name (which may itself contain slashes) is wrapped in parens so it's
+// unambiguous, and the collapsed Prefix is appended after a colon.
+func inspectCompressed(nodes []Node, parent string) (out []string) {
+	for _, n := range nodes {
+		s := ""
+		if parent != "" {
+			s += "[" + parent + "/]"
+		}
+		s += "(" + n.Name + ":" + fmt.Sprint(n.Prefix) + ")"
+		if n.Elem != nil {
+			s += "*"
+		}
+		out = append(out, s)
+		childParent := parent
+		if childParent == "" {
+			childParent = n.Name
+		} else {
+			childParent = childParent + "/" + n.Name
+		}
+		out = append(out, inspectCompressed(n.Children, childParent)...)
+	}
+	return
+}
+
+func TestCompressedTree(t *testing.T) {
+	tests := []struct {
+		elems []Elem
+		want  []string
+		error string
+	}{
+		{
+			elems: mkElems([]elem{}),
+			want:  nil,
+		},
+		{
+			elems: mkElems([]elem{"foo/bar/baz"}),
+			want:  []string{"(foo/bar/baz:[foo bar baz])*"},
+		},
+		{
+			elems: mkElems([]elem{"foo/bar/baz", "foo/bar/qux"}),
+			want: []string{
+				"(foo/bar:[foo bar])",
+				"[foo/bar/](baz:[baz])*",
+				"[foo/bar/](qux:[qux])*",
+			},
+		},
+		{
+			elems: mkElems([]elem{"foo", "foo/bar/baz"}),
+			want: []string{
+				"(foo:[foo])*",
+				"[foo/](bar/baz:[bar baz])*",
+			},
+		},
+		{
+			elems: mkElems([]elem{"foo", "foo/bar/baz", "foo/bar/qux"}),
+			want: []string{
+				"(foo:[foo])*",
+				"[foo/](bar:[bar])",
+				"[foo/bar/](baz:[baz])*",
+				"[foo/bar/](qux:[qux])*",
+			},
+		},
+		{
+			elems: mkElems([]elem{"foo/bar", "baz/qux"}),
+			want: []string{
+				"(baz/qux:[baz qux])*",
+				"(foo/bar:[foo bar])*",
+			},
+		},
+
+		// errors
+		{
+			elems: mkElems([]elem{""}),
+			error: "invalid",
+		},
+		{
+			elems: mkElems([]elem{"bar", "bar"}),
+			error: "duplicate",
+		},
+	}
+
+	for i, test := range tests {
+		label := fmt.Sprintf("elems#%d %v:", i, test.elems)
+		nodes, err := CompressedTree(test.elems)
+		if test.error == "" {
+			if err != nil {
+				t.Errorf("%s unexpected error %q", label, err)
+				continue
+			}
+			if got := inspectCompressed(nodes, ""); !reflect.DeepEqual(test.want, got) {
+				t.Errorf("%s\nwant %v\n got %v", label, test.want, got)
+			}
+		} else if err == nil {
+			t.Errorf("%s want error containing %q, got no error", label, test.error)
+		}
+	}
+}
+
+// flatPaths generates n paths that share a long common prefix chain before branching into a
+// unique leaf, simulating the deep, sparse inputs CompressedTree targets.
+func flatPaths(n int) []Elem {
+	out := make([]Elem, n)
+	for i := 0; i < n; i++ {
+		out[i] = elem("a/b/c/d/e/leaf" + strconv.Itoa(i))
+	}
+	return out
+}
+
+func BenchmarkTree(b *testing.B) {
+	elems := flatPaths(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Tree(elems); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompressedTree(b *testing.B) {
+	elems := flatPaths(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := CompressedTree(elems); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
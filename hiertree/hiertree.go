@@ -76,6 +76,11 @@ type Node struct {
 
 	// Children is the list of child nodes under this node
 	Children []Node
+
+	// Prefix holds the path components collapsed into this node by CompressedTree, in order
+	// (e.g. ["foo", "bar", "baz"] for a Name of "foo/bar/baz"). It is nil for trees produced by
+	// Tree, since those nodes are never compressed.
+	Prefix []string
 }
 
 // Tree arranges elems into a tree based on their hierarchical paths.
@@ -0,0 +1,175 @@
+package hiertree
+
+import "errors"
+
+// Root returns a new, empty root *Node for building a tree incrementally via Insert, rather than
+// materializing a full []Elem up front and calling Tree. Its own Name and Elem are meaningless —
+// only its Children, and whatever Insert grows them into, matter.
+func Root() *Node {
+	return &Node{}
+}
+
+// Find walks n's descendants along components, returning the node at that path. It returns false
+// if any component along the way has no matching child, including when components itself matches
+// no child of n.
+func (n *Node) Find(components []string) (*Node, bool) {
+	cur := n
+	for _, c := range components {
+		next := cur.child(c)
+		if next == nil {
+			return nil, false
+		}
+		cur = next
+	}
+	return cur, true
+}
+
+// child returns the child of n named name, or nil if there is none.
+func (n *Node) child(name string) *Node {
+	for i := range n.Children {
+		if n.Children[i].Name == name {
+			return &n.Children[i]
+		}
+	}
+	return nil
+}
+
+// Insert adds e at components below n, creating stub ancestors (Nodes with a nil Elem) for any
+// path components that don't yet exist, the same way Tree does for a []Elem built all at once. It
+// returns an error, the same way tree does, if components is invalid or an Elem already exists at
+// that exact path; a stub node already at that path is given e instead.
+//
+// The returned *Node points into an ancestor's Children slice; a later Insert or Remove under that
+// same ancestor can reallocate the slice and invalidate it. Callers that need a stable reference
+// after further mutation should look it back up with Find.
+func (n *Node) Insert(components []string, e Elem) (*Node, error) {
+	if !validComponents(components) {
+		return nil, errors.New("invalid node path: " + joinPath(components))
+	}
+	cur := n
+	for _, c := range components {
+		next := cur.child(c)
+		if next == nil {
+			cur.Children = append(cur.Children, Node{Name: c})
+			next = &cur.Children[len(cur.Children)-1]
+		}
+		cur = next
+	}
+	if cur.Elem != nil {
+		return nil, errors.New("duplicate node path: " + joinPath(components))
+	}
+	cur.Elem = e
+	return cur, nil
+}
+
+// Remove deletes the Elem at components below n, reporting whether one was present, and prunes
+// any ancestor stub nodes (nodes with a nil Elem) left with no children as a result.
+func (n *Node) Remove(components []string) (Elem, bool) {
+	if !validComponents(components) {
+		return nil, false
+	}
+	type step struct {
+		parent *Node
+		index  int
+	}
+	stack := make([]step, 0, len(components))
+	cur := n
+	for _, c := range components {
+		idx := -1
+		for i := range cur.Children {
+			if cur.Children[i].Name == c {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, false
+		}
+		stack = append(stack, step{cur, idx})
+		cur = &cur.Children[idx]
+	}
+	e := cur.Elem
+	if e == nil {
+		return nil, false
+	}
+	cur.Elem = nil
+	for i := len(stack) - 1; i >= 0; i-- {
+		s := stack[i]
+		child := s.parent.Children[s.index]
+		if child.Elem != nil || len(child.Children) > 0 {
+			break
+		}
+		s.parent.Children = append(s.parent.Children[:s.index], s.parent.Children[s.index+1:]...)
+	}
+	return e, true
+}
+
+// Rename moves the Elem at from to to below n, creating stub ancestors at to and pruning
+// now-empty stub ancestors at from the same way Insert and Remove would. It validates that to is
+// free before unlinking from, so a rejected Rename leaves the tree exactly as it was. Rename does
+// not move from's children; callers renaming a whole subtree must re-Insert each descendant.
+func (n *Node) Rename(from, to []string) error {
+	if !validComponents(from) {
+		return errors.New("invalid node path: " + joinPath(from))
+	}
+	if !validComponents(to) {
+		return errors.New("invalid node path: " + joinPath(to))
+	}
+	src, ok := n.Find(from)
+	if !ok || src.Elem == nil {
+		return errors.New("invalid node path: " + joinPath(from))
+	}
+	if dst, ok := n.Find(to); ok && dst.Elem != nil {
+		return errors.New("duplicate node path: " + joinPath(to))
+	}
+	e := src.Elem
+	n.Remove(from)
+	_, err := n.Insert(to, e)
+	return err
+}
+
+// Walk calls fn for n and then, in Children order, for each of its descendants, passing each
+// node's path relative to n. It stops and returns the first error fn returns.
+func (n *Node) Walk(fn func(path []string, n *Node) error) error {
+	return n.walk(nil, fn)
+}
+
+func (n *Node) walk(path []string, fn func(path []string, n *Node) error) error {
+	if err := fn(path, n); err != nil {
+		return err
+	}
+	for i := range n.Children {
+		childPath := append(append([]string{}, path...), n.Children[i].Name)
+		if err := n.Children[i].walk(childPath, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validComponents reports whether components is non-empty and contains no empty component.
+func validComponents(components []string) bool {
+	if len(components) == 0 {
+		return false
+	}
+	for _, c := range components {
+		if c == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// joinPath renders components separated by slashes, for error messages. Unlike
+// joinComponents, it tolerates an empty or invalid components so callers can use it while
+// building the message for exactly that error.
+func joinPath(components []string) string {
+	s := ""
+	for i, c := range components {
+		if i > 0 {
+			s += "/"
+		}
+		s += c
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package hiertree
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Router matches request path components against the tree produced from a set of Elems whose
+// path components may contain dynamic segments: a literal ("foo"), a single-segment wildcard
+// (":name"), or a trailing catch-all ("*rest"). At each level of the tree, a literal match takes
+// precedence over a wildcard match, which takes precedence over a catch-all match, backtracking to
+// a less specific sibling when a more specific branch fails to match deeper in the path — the same
+// precedence rule used by Go 1.22's net/http routing patterns.
+type Router struct {
+	roots []Node
+}
+
+// NewRouter builds a Router from elems. It returns an error if elems cannot form a valid Tree, or
+// if any two sibling path components are ambiguous: more than one catch-all among the same
+// siblings, two wildcards with the same variable name, or a catch-all that isn't the last
+// component of its pattern (a catch-all with children could never match past itself, since it
+// always consumes the rest of the path).
+func NewRouter(elems []Elem) (*Router, error) {
+	roots, err := Tree(elems)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRoutes(roots, nil); err != nil {
+		return nil, err
+	}
+	return &Router{roots: roots}, nil
+}
+
+// Match finds the most specific Elem registered with the Router whose pattern matches path, along
+// with the variables captured by any wildcard or catch-all segments along the way.
+func (r *Router) Match(path []string) (Elem, map[string]string, bool) {
+	if len(path) == 0 {
+		return nil, nil, false
+	}
+	return matchNodes(r.roots, path, map[string]string{})
+}
+
+func matchNodes(nodes []Node, comps []string, vars map[string]string) (Elem, map[string]string, bool) {
+	seg, rest := comps[0], comps[1:]
+
+	for _, n := range nodes {
+		if isLiteral(n.Name) && n.Name == seg {
+			if e, v, ok := matchRest(n, rest, vars); ok {
+				return e, v, true
+			}
+		}
+	}
+	for _, n := range nodes {
+		if isWildcard(n.Name) {
+			wvars := cloneVars(vars)
+			wvars[varName(n.Name)] = seg
+			if e, v, ok := matchRest(n, rest, wvars); ok {
+				return e, v, true
+			}
+		}
+	}
+	for _, n := range nodes {
+		if isCatchAll(n.Name) && n.Elem != nil {
+			cvars := cloneVars(vars)
+			cvars[varName(n.Name)] = strings.Join(comps, "/")
+			return n.Elem, cvars, true
+		}
+	}
+	return nil, nil, false
+}
+
+func matchRest(n Node, rest []string, vars map[string]string) (Elem, map[string]string, bool) {
+	if len(rest) == 0 {
+		if n.Elem != nil {
+			return n.Elem, vars, true
+		}
+		return nil, nil, false
+	}
+	return matchNodes(n.Children, rest, vars)
+}
+
+func cloneVars(vars map[string]string) map[string]string {
+	v := make(map[string]string, len(vars)+1)
+	for k, val := range vars {
+		v[k] = val
+	}
+	return v
+}
+
+func isWildcard(name string) bool { return strings.HasPrefix(name, ":") }
+func isCatchAll(name string) bool { return strings.HasPrefix(name, "*") }
+func isLiteral(name string) bool  { return !isWildcard(name) && !isCatchAll(name) }
+
+func varName(name string) string { return name[1:] }
+
+// validateRoutes rejects any pair of siblings in nodes (or in their descendants) whose patterns
+// overlap ambiguously.
+func validateRoutes(nodes []Node, prefix []string) error {
+	var sawCatchAll bool
+	wildcards := make(map[string]bool)
+	for _, n := range nodes {
+		switch {
+		case isCatchAll(n.Name):
+			if sawCatchAll {
+				return fmt.Errorf("ambiguous node path: multiple catch-all siblings at: %q", prefix)
+			}
+			sawCatchAll = true
+			if len(n.Children) > 0 {
+				return fmt.Errorf("ambiguous node path: catch-all has children at: %q", joined(prefix, n.Name))
+			}
+		case isWildcard(n.Name):
+			name := varName(n.Name)
+			if wildcards[name] {
+				return fmt.Errorf("ambiguous node path: colliding wildcard %q at: %q", n.Name, prefix)
+			}
+			wildcards[name] = true
+		}
+	}
+	for _, n := range nodes {
+		if err := validateRoutes(n.Children, joined(prefix, n.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
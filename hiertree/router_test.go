@@ -0,0 +1,86 @@
+package hiertree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func mustRouter(t *testing.T, paths ...string) *Router {
+	t.Helper()
+	es := make([]elem, len(paths))
+	for i, p := range paths {
+		es[i] = elem(p)
+	}
+	r, err := NewRouter(mkElems(es))
+	if err != nil {
+		t.Fatalf("NewRouter(%v): unexpected error %q", paths, err)
+	}
+	return r
+}
+
+func TestRouterPrecedence(t *testing.T) {
+	r := mustRouter(t, "foo/:x/baz", "foo/*rest")
+
+	e, vars, ok := r.Match([]string{"foo", "a", "baz"})
+	if !ok {
+		t.Fatalf("foo/a/baz: want match, got none")
+	}
+	if string(e.(elem)) != "foo/:x/baz" {
+		t.Errorf("foo/a/baz: want match on %q, got %q", "foo/:x/baz", e)
+	}
+	if want := map[string]string{"x": "a"}; !reflect.DeepEqual(want, vars) {
+		t.Errorf("foo/a/baz: want vars %v, got %v", want, vars)
+	}
+
+	e, vars, ok = r.Match([]string{"foo", "a", "b", "c"})
+	if !ok {
+		t.Fatalf("foo/a/b/c: want match, got none")
+	}
+	if string(e.(elem)) != "foo/*rest" {
+		t.Errorf("foo/a/b/c: want match on %q, got %q", "foo/*rest", e)
+	}
+	if want := map[string]string{"rest": "a/b/c"}; !reflect.DeepEqual(want, vars) {
+		t.Errorf("foo/a/b/c: want vars %v, got %v", want, vars)
+	}
+}
+
+func TestRouterLiteralBeatsWildcard(t *testing.T) {
+	r := mustRouter(t, "foo/bar", "foo/:x")
+
+	e, vars, ok := r.Match([]string{"foo", "bar"})
+	if !ok || string(e.(elem)) != "foo/bar" {
+		t.Fatalf("foo/bar: want literal match, got %v ok=%v", e, ok)
+	}
+	if len(vars) != 0 {
+		t.Errorf("foo/bar: want no vars, got %v", vars)
+	}
+
+	e, _, ok = r.Match([]string{"foo", "qux"})
+	if !ok || string(e.(elem)) != "foo/:x" {
+		t.Fatalf("foo/qux: want wildcard match, got %v ok=%v", e, ok)
+	}
+}
+
+func TestRouterNoMatch(t *testing.T) {
+	r := mustRouter(t, "foo/bar")
+	if _, _, ok := r.Match([]string{"foo", "baz"}); ok {
+		t.Errorf("foo/baz: want no match")
+	}
+}
+
+func TestRouterAmbiguous(t *testing.T) {
+	tests := [][]string{
+		{"foo/*a", "foo/*b"},
+		{"foo/:x/bar", "foo/:x/baz", "foo/:x/bar"}, // plain duplicate node path
+		{"foo/*rest/bar"},                          // catch-all isn't the last component; "bar" could never match
+	}
+	for _, paths := range tests {
+		es := make([]elem, len(paths))
+		for i, p := range paths {
+			es[i] = elem(p)
+		}
+		if _, err := NewRouter(mkElems(es)); err == nil {
+			t.Errorf("NewRouter(%v): want error, got none", paths)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package hiertree
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNodeInsertFind(t *testing.T) {
+	root := Root()
+	n, err := root.Insert([]string{"a", "b"}, elem("a/b"))
+	if err != nil {
+		t.Fatalf("Insert: unexpected error %q", err)
+	}
+	if n.Elem != elem("a/b") {
+		t.Errorf("Insert: want returned node's Elem to be set, got %v", n.Elem)
+	}
+
+	found, ok := root.Find([]string{"a"})
+	if !ok {
+		t.Fatalf("Find(a): want found")
+	}
+	if found.Elem != nil {
+		t.Errorf("Find(a): want a stub (nil Elem), got %v", found.Elem)
+	}
+	if len(found.Children) != 1 || found.Children[0].Name != "b" {
+		t.Errorf("Find(a): want one child named b, got %v", found.Children)
+	}
+
+	if _, ok := root.Find([]string{"x"}); ok {
+		t.Errorf("Find(x): want not found")
+	}
+}
+
+func TestNodeInsertDuplicate(t *testing.T) {
+	root := Root()
+	if _, err := root.Insert([]string{"a"}, elem("a")); err != nil {
+		t.Fatalf("Insert: unexpected error %q", err)
+	}
+	_, err := root.Insert([]string{"a"}, elem("a"))
+	if err == nil || !errorContains(err, "duplicate") {
+		t.Errorf("Insert: want duplicate error, got %v", err)
+	}
+}
+
+func TestNodeInsertInvalid(t *testing.T) {
+	root := Root()
+	for _, components := range [][]string{nil, {}, {""}, {"a", ""}} {
+		if _, err := root.Insert(components, elem("x")); err == nil || !errorContains(err, "invalid") {
+			t.Errorf("Insert(%v): want invalid error, got %v", components, err)
+		}
+	}
+}
+
+func TestNodeRemove(t *testing.T) {
+	root := Root()
+	mustInsert(t, root, []string{"a", "b"}, elem("a/b"))
+	mustInsert(t, root, []string{"a", "c"}, elem("a/c"))
+
+	e, ok := root.Remove([]string{"a", "b"})
+	if !ok || e != elem("a/b") {
+		t.Fatalf("Remove(a/b): want (a/b, true), got (%v, %v)", e, ok)
+	}
+	// a/c still lives under the (stub) a node, so a must survive.
+	a, ok := root.Find([]string{"a"})
+	if !ok {
+		t.Fatalf("Find(a): want found")
+	}
+	if len(a.Children) != 1 || a.Children[0].Name != "c" {
+		t.Errorf("Find(a).Children: want just [c], got %v", a.Children)
+	}
+
+	e, ok = root.Remove([]string{"a", "c"})
+	if !ok || e != elem("a/c") {
+		t.Fatalf("Remove(a/c): want (a/c, true), got (%v, %v)", e, ok)
+	}
+	// Nothing lives under a any more, so it must be pruned along with c.
+	if _, ok := root.Find([]string{"a"}); ok {
+		t.Errorf("Find(a): want pruned, but still found")
+	}
+	if len(root.Children) != 0 {
+		t.Errorf("root.Children: want none left, got %v", root.Children)
+	}
+}
+
+func TestNodeRemoveMissing(t *testing.T) {
+	root := Root()
+	mustInsert(t, root, []string{"a"}, elem("a"))
+
+	if _, ok := root.Remove([]string{"x"}); ok {
+		t.Errorf("Remove(x): want not found")
+	}
+	// a has no child b, so there is nothing at "a/b" to remove.
+	if _, ok := root.Remove([]string{"a", "b"}); ok {
+		t.Errorf("Remove(a/b): want not found")
+	}
+}
+
+func TestNodeRename(t *testing.T) {
+	root := Root()
+	mustInsert(t, root, []string{"a", "b"}, elem("a/b"))
+	mustInsert(t, root, []string{"a", "b", "w"}, elem("a/b/w"))
+
+	if err := root.Rename([]string{"a", "b"}, []string{"a", "z"}); err != nil {
+		t.Fatalf("Rename: unexpected error %q", err)
+	}
+
+	if _, ok := root.Find([]string{"a", "b"}); !ok {
+		t.Errorf("Find(a/b): want the stub left behind by the rename to still be found")
+	} else if n, _ := root.Find([]string{"a", "b"}); n.Elem != nil {
+		t.Errorf("Find(a/b): want a stub (nil Elem) now that its Elem moved, got %v", n.Elem)
+	}
+	if n, ok := root.Find([]string{"a", "b", "w"}); !ok || n.Elem != elem("a/b/w") {
+		t.Errorf("Find(a/b/w): want the untouched child w to remain, got (%v, %v)", n, ok)
+	}
+	if n, ok := root.Find([]string{"a", "z"}); !ok || n.Elem != elem("a/b") {
+		t.Errorf("Find(a/z): want the renamed Elem, got (%v, %v)", n, ok)
+	}
+}
+
+func TestNodeRenameDestinationOccupied(t *testing.T) {
+	root := Root()
+	mustInsert(t, root, []string{"a"}, elem("a"))
+	mustInsert(t, root, []string{"b"}, elem("b"))
+
+	err := root.Rename([]string{"a"}, []string{"b"})
+	if err == nil || !errorContains(err, "duplicate") {
+		t.Errorf("Rename: want duplicate error, got %v", err)
+	}
+	// A rejected Rename must leave both sides untouched.
+	if n, ok := root.Find([]string{"a"}); !ok || n.Elem != elem("a") {
+		t.Errorf("Find(a): want the source untouched, got (%v, %v)", n, ok)
+	}
+	if n, ok := root.Find([]string{"b"}); !ok || n.Elem != elem("b") {
+		t.Errorf("Find(b): want the destination untouched, got (%v, %v)", n, ok)
+	}
+}
+
+func TestNodeRenameMissingSource(t *testing.T) {
+	root := Root()
+	if err := root.Rename([]string{"a"}, []string{"b"}); err == nil || !errorContains(err, "invalid") {
+		t.Errorf("Rename: want invalid error, got %v", err)
+	}
+}
+
+func TestNodeWalk(t *testing.T) {
+	root := Root()
+	mustInsert(t, root, []string{"a", "b"}, elem("a/b"))
+	mustInsert(t, root, []string{"a", "c"}, elem("a/c"))
+	mustInsert(t, root, []string{"d"}, elem("d"))
+
+	var visited [][]string
+	err := root.Walk(func(path []string, n *Node) error {
+		visited = append(visited, append([]string{}, path...))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: unexpected error %q", err)
+	}
+	want := [][]string{{}, {"a"}, {"a", "b"}, {"a", "c"}, {"d"}}
+	if !reflect.DeepEqual(want, visited) {
+		t.Errorf("Walk: want %v, got %v", want, visited)
+	}
+}
+
+func TestNodeWalkStopsOnError(t *testing.T) {
+	root := Root()
+	mustInsert(t, root, []string{"a", "b"}, elem("a/b"))
+	mustInsert(t, root, []string{"d"}, elem("d"))
+
+	sentinel := errors.New("stop")
+	var visited int
+	err := root.Walk(func(path []string, n *Node) error {
+		visited++
+		if n.Name == "a" {
+			return sentinel
+		}
+		return nil
+	})
+	if err != sentinel {
+		t.Fatalf("Walk: want sentinel error, got %v", err)
+	}
+	if visited != 2 { // root, then "a"
+		t.Errorf("Walk: want 2 visits before stopping, got %d", visited)
+	}
+}
+
+func mustInsert(t *testing.T, n *Node, components []string, e Elem) {
+	t.Helper()
+	if _, err := n.Insert(components, e); err != nil {
+		t.Fatalf("Insert(%v): unexpected error %q", components, err)
+	}
+}
+
+func errorContains(err error, substr string) bool {
+	return err != nil && strings.Contains(err.Error(), substr)
+}
@@ -0,0 +1,323 @@
+package hiertree
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Path is a compiled Query expression. Compiling once with CompilePath and reusing the result
+// across many Eval calls avoids re-parsing expr for every tree, similar in spirit to a
+// precompiled etree path.
+type Path struct {
+	steps []queryStep
+}
+
+// Query evaluates a small subset of XPath-style expr against nodes (a tree produced by Tree or
+// List's underlying nodes). nodes is treated the way a document's root elements are in XPath: a
+// bare step ("name" or "*") matches entries of nodes itself, and each further step descends into
+// the children of whatever the previous step matched. Supported syntax:
+//
+//	.          the current selection, unchanged
+//	..         the parent of the current selection (empty once it climbs above nodes)
+//	*          all children of the current selection
+//	//         the current selection plus all of its descendants
+//	name       the children (or, for the first step, the entries of nodes) named name
+//	[n]        the n'th result so far, 1-based; negative counts from the end
+//	[@leaf]    keep only results with a non-nil Elem
+//	[@stub]    keep only results with a nil Elem
+//	[@a='v']   keep only results where the attribute a (see RegisterAttr) equals v
+//
+// Steps are separated by "/". Query is a convenience for CompilePath(expr) followed by Eval;
+// callers issuing the same expr against many trees should compile it once instead.
+func Query(nodes []Node, expr string) ([]Node, error) {
+	p, err := CompilePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Eval(nodes), nil
+}
+
+// CompilePath parses expr into a reusable Path. See Query for the supported syntax.
+func CompilePath(expr string) (*Path, error) {
+	if expr == "" {
+		return nil, fmt.Errorf("hiertree: empty query")
+	}
+	// Turn every "//" into a "/\x00/" sentinel segment so a single strings.Split on "/" can
+	// recognize the descendant-or-self axis wherever it appears, including at the start.
+	marked := strings.TrimPrefix(strings.ReplaceAll(expr, "//", "/\x00/"), "/")
+
+	var steps []queryStep
+	for _, seg := range strings.Split(marked, "/") {
+		if seg == "" {
+			continue
+		}
+		st, err := parseQueryStep(seg)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, st)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("hiertree: empty query: %q", expr)
+	}
+	return &Path{steps: steps}, nil
+}
+
+// Eval evaluates the compiled path against nodes. See Query for the supported syntax.
+func (p *Path) Eval(nodes []Node) []Node {
+	root := &queryCtx{node: Node{Children: nodes}, synthetic: true}
+	ctxs := []*queryCtx{root}
+	for _, st := range p.steps {
+		ctxs = st.eval(ctxs)
+	}
+	// descendantOrSelf keeps the synthetic root around (so a name/children step right after it
+	// can still see nodes' own entries as its children), but the synthetic root is never itself
+	// a real result, so it's dropped here rather than in every step that might pass it through.
+	var out []Node
+	for _, c := range ctxs {
+		if c.synthetic {
+			continue
+		}
+		out = append(out, c.node)
+	}
+	return out
+}
+
+// queryCtx wraps a Node with a link to its parent context, so that the ".." axis can climb back
+// up a tree that Node itself doesn't store parent pointers for. The root queryCtx passed to
+// Eval's first step is synthetic: it's never itself part of a result, and its children (the
+// nodes Eval was called with) are treated as having no parent.
+type queryCtx struct {
+	node      Node
+	parent    *queryCtx
+	synthetic bool
+}
+
+// childCtx returns the queryCtx for child n of c.
+func (c *queryCtx) childCtx(n Node) *queryCtx {
+	parent := c
+	if c.synthetic {
+		parent = nil
+	}
+	return &queryCtx{node: n, parent: parent}
+}
+
+type queryAxis int
+
+const (
+	axisSelf queryAxis = iota
+	axisParent
+	axisChildren
+	axisDescendantOrSelf
+	axisChildByName
+)
+
+type queryStep struct {
+	axis       queryAxis
+	name       string // for axisChildByName
+	predicates []queryPredicate
+}
+
+func (st queryStep) eval(in []*queryCtx) []*queryCtx {
+	var out []*queryCtx
+	switch st.axis {
+	case axisSelf:
+		for _, c := range in {
+			if c.synthetic {
+				for i := range c.node.Children {
+					out = append(out, c.childCtx(c.node.Children[i]))
+				}
+				continue
+			}
+			out = append(out, c)
+		}
+	case axisParent:
+		for _, c := range in {
+			if c.parent != nil {
+				out = append(out, c.parent)
+			}
+		}
+	case axisChildren:
+		for _, c := range in {
+			for i := range c.node.Children {
+				out = append(out, c.childCtx(c.node.Children[i]))
+			}
+		}
+	case axisChildByName:
+		for _, c := range in {
+			for i := range c.node.Children {
+				if c.node.Children[i].Name == st.name {
+					out = append(out, c.childCtx(c.node.Children[i]))
+				}
+			}
+		}
+	case axisDescendantOrSelf:
+		for _, c := range in {
+			out = append(out, descendantOrSelf(c)...)
+		}
+	}
+	for _, p := range st.predicates {
+		out = p.apply(out)
+	}
+	return out
+}
+
+// descendantOrSelf returns c followed by all of its descendants. c is included even when it's the
+// synthetic root: that's never itself a real result (Eval strips it out at the end), but a name or
+// children step immediately following "//" needs it in scope to see nodes' own top-level entries
+// as its children, the same way it would for any other node's children.
+func descendantOrSelf(c *queryCtx) []*queryCtx {
+	out := []*queryCtx{c}
+	for i := range c.node.Children {
+		out = append(out, descendantOrSelf(c.childCtx(c.node.Children[i]))...)
+	}
+	return out
+}
+
+type queryPredicateKind int
+
+const (
+	predIndex queryPredicateKind = iota
+	predLeaf
+	predStub
+	predAttrExists
+	predAttrEq
+)
+
+type queryPredicate struct {
+	kind  queryPredicateKind
+	index int
+	attr  string
+	value string
+}
+
+// apply filters ctxs, interpreting an index predicate as a position within ctxs as a whole (the
+// combined result of the preceding step across all of its current contexts) rather than per
+// individual parent, which keeps this implementation a tractable subset of XPath's position()
+// semantics.
+func (p queryPredicate) apply(ctxs []*queryCtx) []*queryCtx {
+	switch p.kind {
+	case predIndex:
+		i := p.index
+		if i < 0 {
+			i = len(ctxs) + i + 1
+		}
+		if i < 1 || i > len(ctxs) {
+			return nil
+		}
+		return []*queryCtx{ctxs[i-1]}
+	case predLeaf:
+		return filterCtxs(ctxs, func(n Node) bool { return n.Elem != nil })
+	case predStub:
+		return filterCtxs(ctxs, func(n Node) bool { return n.Elem == nil })
+	case predAttrExists:
+		fn := getAttrFunc(p.attr)
+		return filterCtxs(ctxs, func(n Node) bool { return fn != nil && fn(n.Elem) != "" })
+	case predAttrEq:
+		fn := getAttrFunc(p.attr)
+		return filterCtxs(ctxs, func(n Node) bool { return fn != nil && fn(n.Elem) == p.value })
+	}
+	return ctxs
+}
+
+func filterCtxs(ctxs []*queryCtx, keep func(Node) bool) []*queryCtx {
+	var out []*queryCtx
+	for _, c := range ctxs {
+		if keep(c.node) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func parseQueryStep(seg string) (queryStep, error) {
+	name := seg
+	var predExprs []string
+	if i := strings.IndexByte(seg, '['); i >= 0 {
+		name = seg[:i]
+		rest := seg[i:]
+		for rest != "" {
+			if rest[0] != '[' {
+				return queryStep{}, fmt.Errorf("hiertree: invalid query segment %q", seg)
+			}
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return queryStep{}, fmt.Errorf("hiertree: unterminated predicate in %q", seg)
+			}
+			predExprs = append(predExprs, rest[1:end])
+			rest = rest[end+1:]
+		}
+	}
+
+	var st queryStep
+	switch name {
+	case "\x00":
+		st.axis = axisDescendantOrSelf
+	case ".":
+		st.axis = axisSelf
+	case "..":
+		st.axis = axisParent
+	case "*":
+		st.axis = axisChildren
+	default:
+		st.axis = axisChildByName
+		st.name = name
+	}
+
+	for _, pe := range predExprs {
+		p, err := parseQueryPredicate(pe)
+		if err != nil {
+			return queryStep{}, err
+		}
+		st.predicates = append(st.predicates, p)
+	}
+	return st, nil
+}
+
+func parseQueryPredicate(expr string) (queryPredicate, error) {
+	if strings.HasPrefix(expr, "@") {
+		rest := expr[1:]
+		if eq := strings.IndexByte(rest, '='); eq >= 0 {
+			return queryPredicate{
+				kind:  predAttrEq,
+				attr:  rest[:eq],
+				value: strings.Trim(rest[eq+1:], `'"`),
+			}, nil
+		}
+		switch rest {
+		case "leaf":
+			return queryPredicate{kind: predLeaf}, nil
+		case "stub":
+			return queryPredicate{kind: predStub}, nil
+		default:
+			return queryPredicate{kind: predAttrExists, attr: rest}, nil
+		}
+	}
+	n, err := strconv.Atoi(expr)
+	if err != nil {
+		return queryPredicate{}, fmt.Errorf("hiertree: invalid predicate %q", expr)
+	}
+	return queryPredicate{kind: predIndex, index: n}, nil
+}
+
+var (
+	attrFuncsMu sync.RWMutex
+	attrFuncs   = map[string]func(Elem) string{}
+)
+
+// RegisterAttr registers a named attribute function that [@name] and [@name='value'] predicates
+// can use to test an Elem. It's typically called once at init time by a package that defines its
+// own Elem implementation.
+func RegisterAttr(name string, fn func(Elem) string) {
+	attrFuncsMu.Lock()
+	defer attrFuncsMu.Unlock()
+	attrFuncs[name] = fn
+}
+
+func getAttrFunc(name string) func(Elem) string {
+	attrFuncsMu.RLock()
+	defer attrFuncsMu.RUnlock()
+	return attrFuncs[name]
+}
@@ -0,0 +1,150 @@
+package hiertree
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+func queryNames(t *testing.T, nodes []Node, expr string) []string {
+	t.Helper()
+	got, err := Query(nodes, expr)
+	if err != nil {
+		t.Fatalf("Query(%q): unexpected error %q", expr, err)
+	}
+	names := make([]string, len(got))
+	for i, n := range got {
+		names[i] = n.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// queryTestTree returns a tree whose node Names are unique, so tests can identify results by Name
+// alone:
+//
+//	a* -> b* -> d*
+//	      c*
+//	e*
+func queryTestTree(t *testing.T) []Node {
+	t.Helper()
+	nodes, err := Tree(mkElems([]elem{"a", "a/b", "a/b/d", "a/c", "e"}))
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+	return nodes
+}
+
+func TestQueryOperators(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{".", []string{"a", "e"}},
+		{"*", []string{"a", "e"}}, // from the implicit root, "*" selects nodes themselves
+		{"//", []string{"a", "b", "c", "d", "e"}},
+		{"a", []string{"a"}},
+		{"a/b", []string{"b"}},
+		{"a/*", []string{"b", "c"}},
+		{"a//", []string{"a", "b", "c", "d"}},
+		{"a/b/..", []string{"a"}},
+		{"..", nil},
+		{"a[1]", []string{"a"}},
+		{"*[1]", []string{"a"}},
+		{"*[-1]", []string{"e"}},
+		{"*[2]", []string{"e"}},
+		{"*[3]", nil},
+		{"*[@leaf]", []string{"a", "e"}},
+		{"a/b/*[@stub]", nil},
+		{"a/*[@stub]", nil},
+	}
+	nodes := queryTestTree(t)
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			if got := queryNames(t, nodes, test.expr); fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("Query(%q): want %v, got %v", test.expr, test.want, got)
+			}
+		})
+	}
+}
+
+func TestQueryDescendantOrSelfByName(t *testing.T) {
+	// "foo" is both a top-level entry and nested under "bar"; "//foo" must find both, since the
+	// synthetic document root is part of the descendant-or-self axis too.
+	nodes, err := Tree(mkElems([]elem{"foo", "bar/foo"}))
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+	if got := queryNames(t, nodes, "//foo"); fmt.Sprint(got) != fmt.Sprint([]string{"foo", "foo"}) {
+		t.Errorf("//foo: want [foo foo], got %v", got)
+	}
+}
+
+func TestQueryStub(t *testing.T) {
+	// "a/b" and "x/y" have no elems at "a" or "x", so both top-level entries are genuine stubs.
+	nodes, err := Tree(mkElems([]elem{"a/b", "x/y"}))
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+	if got := queryNames(t, nodes, "*[@stub]"); fmt.Sprint(got) != fmt.Sprint([]string{"a", "x"}) {
+		t.Errorf("*[@stub]: want [a x], got %v", got)
+	}
+	if got := queryNames(t, nodes, "*[@leaf]"); len(got) != 0 {
+		t.Errorf("*[@leaf]: want none, got %v", got)
+	}
+}
+
+type fileElem struct {
+	elem
+	kind string
+}
+
+func TestQueryRegisterAttr(t *testing.T) {
+	RegisterAttr("kind", func(e Elem) string {
+		if f, ok := e.(fileElem); ok {
+			return f.kind
+		}
+		return ""
+	})
+
+	nodes, err := Tree([]Elem{
+		fileElem{"a/b", "dir"},
+		fileElem{"a/c", "file"},
+	})
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+
+	if got := queryNames(t, nodes, "a/*[@kind='file']"); fmt.Sprint(got) != fmt.Sprint([]string{"c"}) {
+		t.Errorf("[@kind='file']: want [c], got %v", got)
+	}
+	if got := queryNames(t, nodes, "a/*[@kind]"); fmt.Sprint(got) != fmt.Sprint([]string{"b", "c"}) {
+		t.Errorf("[@kind]: want [b c], got %v", got)
+	}
+}
+
+func TestQueryCompileErrors(t *testing.T) {
+	for _, expr := range []string{"", "foo[bar]", "foo[1"} {
+		if _, err := CompilePath(expr); err == nil {
+			t.Errorf("CompilePath(%q): want error, got none", expr)
+		}
+	}
+}
+
+func TestQueryCompiledReuse(t *testing.T) {
+	p, err := CompilePath("*")
+	if err != nil {
+		t.Fatalf("CompilePath: unexpected error %q", err)
+	}
+	a := queryTestTree(t)
+	b, err := Tree(mkElems([]elem{"x", "x/y"}))
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+	if got := p.Eval(a); len(got) != 2 {
+		t.Errorf("Eval(a): want 2 results, got %d", len(got))
+	}
+	if got := p.Eval(b); len(got) != 1 || got[0].Name != "x" {
+		t.Errorf("Eval(b): want [x], got %v", got)
+	}
+}
@@ -0,0 +1,319 @@
+package hiertree
+
+import (
+	"sort"
+	"strings"
+)
+
+// SuggestMode selects how Suggest and Index.Suggest match query against a candidate path.
+type SuggestMode int
+
+const (
+	// ModeExactPrefix matches when query is a prefix of the path or of one of its components.
+	ModeExactPrefix SuggestMode = iota
+	// ModeSubsequence matches when every rune of query appears, in order, somewhere in the path.
+	ModeSubsequence
+	// ModeLevenshtein matches when some substring of the path is within MaxDistance edits of
+	// query.
+	ModeLevenshtein
+)
+
+// SuggestOptions configures Suggest and Index.Suggest.
+type SuggestOptions struct {
+	// MaxResults caps the number of Suggestions returned. Zero means unlimited.
+	MaxResults int
+
+	// CaseInsensitive makes matching ignore case.
+	CaseInsensitive bool
+
+	// Mode selects the matching algorithm.
+	Mode SuggestMode
+
+	// MaxDistance is the maximum edit distance allowed by ModeLevenshtein. It's ignored by the
+	// other modes.
+	MaxDistance int
+}
+
+// Suggestion is a single ranked candidate returned by Suggest or Index.Suggest.
+type Suggestion struct {
+	// Node is the matched node.
+	Node Node
+
+	// Path is the full slash-joined path from the root to Node.
+	Path string
+}
+
+// Suggest returns ranked candidates from nodes for interactive path completion. Callers issuing
+// many queries against the same nodes should build an Index once with NewIndex and call
+// Index.Suggest instead, which avoids re-flattening nodes on every call.
+func Suggest(nodes []Node, query string, opts SuggestOptions) []Suggestion {
+	return NewIndex(nodes).Suggest(query, opts)
+}
+
+// indexedPath is a single flattened path within an Index.
+type indexedPath struct {
+	node  Node
+	path  string
+	lower string
+	depth int
+}
+
+// Index is a corpus of flattened paths built once via NewIndex and reused across many Suggest
+// calls, so repeated queries over the same nodes don't pay the cost of re-walking the tree. It
+// also holds a per-trigram inverted index over path text, so a query can narrow the candidate set
+// before running the more expensive match algorithms, similar to the fuzzy-trie approach used by
+// file-picker UIs.
+type Index struct {
+	paths    []indexedPath
+	trigrams map[string][]int
+}
+
+// NewIndex builds an Index over the flattened paths of nodes.
+func NewIndex(nodes []Node) *Index {
+	idx := &Index{trigrams: make(map[string][]int)}
+	idx.addPaths(nodes, "", 0)
+	for i, p := range idx.paths {
+		for _, g := range trigrams(p.lower) {
+			grams := idx.trigrams[g]
+			if len(grams) == 0 || grams[len(grams)-1] != i {
+				idx.trigrams[g] = append(grams, i)
+			}
+		}
+	}
+	return idx
+}
+
+func (idx *Index) addPaths(nodes []Node, parent string, depth int) {
+	for _, n := range nodes {
+		path := n.Name
+		if parent != "" {
+			path = parent + "/" + n.Name
+		}
+		idx.paths = append(idx.paths, indexedPath{node: n, path: path, lower: strings.ToLower(path), depth: depth})
+		idx.addPaths(n.Children, path, depth+1)
+	}
+}
+
+// Suggest returns ranked candidates matching query.
+func (idx *Index) Suggest(query string, opts SuggestOptions) []Suggestion {
+	q := query
+	if opts.CaseInsensitive {
+		q = strings.ToLower(q)
+	}
+
+	type scored struct {
+		p        indexedPath
+		span     int
+		boundary bool
+	}
+	// The trigram index only helps narrow candidates for matches that contain a whole trigram of q
+	// verbatim. ModeSubsequence's whole point is to match scattered characters, so it always scans
+	// the full candidate set. Each ModeLevenshtein edit breaks at most three of q's trigrams, so
+	// that mode uses the index only when q has more trigrams than MaxDistance edits can break.
+	cands := idx.candidates(strings.ToLower(q))
+	if opts.Mode == ModeSubsequence || (opts.Mode == ModeLevenshtein && len([]rune(q))-2 <= 3*opts.MaxDistance) {
+		cands = idx.allIndices()
+	}
+
+	var results []scored
+	for _, i := range cands {
+		p := idx.paths[i]
+		s := p.path
+		if opts.CaseInsensitive {
+			s = p.lower
+		}
+		switch opts.Mode {
+		case ModeExactPrefix:
+			if start, ok := prefixMatch(s, q); ok {
+				results = append(results, scored{p, len(q), start == 0 || s[start-1] == '/'})
+			}
+		case ModeSubsequence:
+			if start, end, ok := subsequenceMatch(s, q); ok {
+				results = append(results, scored{p, end - start + 1, start == 0 || s[start-1] == '/'})
+			}
+		case ModeLevenshtein:
+			if start, end, ok := levenshteinMatch(s, q, opts.MaxDistance); ok {
+				results = append(results, scored{p, end - start + 1, start == 0 || s[start-1] == '/'})
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.span != b.span {
+			return a.span < b.span
+		}
+		if a.boundary != b.boundary {
+			return a.boundary
+		}
+		if a.p.depth != b.p.depth {
+			return a.p.depth < b.p.depth
+		}
+		return a.p.path < b.p.path
+	})
+
+	if opts.MaxResults > 0 && len(results) > opts.MaxResults {
+		results = results[:opts.MaxResults]
+	}
+	out := make([]Suggestion, len(results))
+	for i, r := range results {
+		out[i] = Suggestion{Node: r.p.node, Path: r.p.path}
+	}
+	return out
+}
+
+// allIndices returns the indices of every path in idx.paths.
+func (idx *Index) allIndices() []int {
+	all := make([]int, len(idx.paths))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// candidates returns the indices of paths[] worth trying against q, using the trigram index to
+// avoid a full scan when q is long enough to produce at least one trigram. q must already be
+// lower-cased: idx.trigrams is always built from the lower-cased path, regardless of whether the
+// caller's match step is case-sensitive, since the prefilter only narrows candidates and never
+// itself decides the match.
+func (idx *Index) candidates(q string) []int {
+	grams := trigrams(q)
+	if len([]rune(q)) < 3 {
+		return idx.allIndices()
+	}
+	seen := make(map[int]bool)
+	var out []int
+	for _, g := range grams {
+		for _, i := range idx.trigrams[g] {
+			if !seen[i] {
+				seen[i] = true
+				out = append(out, i)
+			}
+		}
+	}
+	return out
+}
+
+// trigrams returns the overlapping 3-rune substrings of s, or a single entry containing the whole
+// of s if it's shorter than that.
+func trigrams(s string) []string {
+	r := []rune(s)
+	if len(r) < 3 {
+		if len(r) == 0 {
+			return nil
+		}
+		return []string{string(r)}
+	}
+	grams := make([]string, 0, len(r)-2)
+	for i := 0; i+3 <= len(r); i++ {
+		grams = append(grams, string(r[i:i+3]))
+	}
+	return grams
+}
+
+// prefixMatch reports whether q occurs as a prefix of s or of one of s's "/"-separated
+// components, returning the byte offset where it starts.
+func prefixMatch(s, q string) (start int, ok bool) {
+	if strings.HasPrefix(s, q) {
+		return 0, true
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '/' && strings.HasPrefix(s[i+1:], q) {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// subsequenceMatch finds the tightest byte range [start, end] of s containing every rune of q, in
+// order, as a subsequence, using the standard "minimum window subsequence" technique: after
+// finding where a forward scan from i first completes a match, tighten its start by scanning
+// backward, then resume the forward scan just past that tightened start (not past the match's
+// end) to look for a shorter window elsewhere in s, keeping the smallest one found.
+func subsequenceMatch(s, q string) (start, end int, ok bool) {
+	if q == "" {
+		return 0, -1, true
+	}
+	for i := 0; i < len(s); {
+		si, qi := i, 0
+		for si < len(s) && qi < len(q) {
+			if s[si] == q[qi] {
+				qi++
+			}
+			si++
+		}
+		if qi < len(q) {
+			break // no completion of q starting at or after i
+		}
+		windowEnd := si - 1
+		windowStart := windowEnd
+		qi = len(q) - 1
+		for j := windowEnd; j >= i; j-- {
+			if s[j] == q[qi] {
+				qi--
+				if qi < 0 {
+					windowStart = j
+					break
+				}
+			}
+		}
+		if !ok || windowEnd-windowStart < end-start {
+			start, end, ok = windowStart, windowEnd, true
+		}
+		i = windowStart + 1
+	}
+	return start, end, ok
+}
+
+// levenshteinMatch finds a substring of s within maxDist edits of q, using the standard
+// free-start-position variant of the Levenshtein DP (Sellers' algorithm), and returns its
+// tightest byte range.
+func levenshteinMatch(s, q string, maxDist int) (start, end int, ok bool) {
+	if q == "" {
+		return 0, -1, true
+	}
+	m, n := len(q), len(s)
+	prevDist := make([]int, n+1)
+	prevStart := make([]int, n+1)
+	for j := range prevStart {
+		prevStart[j] = j
+	}
+	curDist := make([]int, n+1)
+	curStart := make([]int, n+1)
+	for i := 1; i <= m; i++ {
+		curDist[0] = i
+		curStart[0] = 0
+		for j := 1; j <= n; j++ {
+			sub := prevDist[j-1]
+			if q[i-1] != s[j-1] {
+				sub++
+			}
+			best, bestStart := sub, prevStart[j-1]
+			if del := prevDist[j] + 1; del < best {
+				best, bestStart = del, prevStart[j]
+			}
+			if ins := curDist[j-1] + 1; ins < best {
+				best, bestStart = ins, curStart[j-1]
+			}
+			curDist[j], curStart[j] = best, bestStart
+		}
+		prevDist, curDist = curDist, prevDist
+		prevStart, curStart = curStart, prevStart
+	}
+
+	bestJ, bestDist := -1, maxDist+1
+	for j := 0; j <= n; j++ {
+		if prevDist[j] <= maxDist && prevDist[j] < bestDist {
+			bestDist, bestJ = prevDist[j], j
+		}
+	}
+	if bestJ < 0 {
+		return 0, 0, false
+	}
+	start = prevStart[bestJ]
+	end = bestJ - 1
+	if end < start {
+		end = start
+	}
+	return start, end, true
+}
@@ -0,0 +1,132 @@
+package hiertree
+
+import (
+	"reflect"
+	"testing"
+)
+
+func suggestTestTree(t *testing.T) []Node {
+	t.Helper()
+	nodes, err := Tree(mkElems([]elem{
+		"src/main.go",
+		"src/utils/helper.go",
+		"docs/readme.md",
+		"test/test_main.go",
+	}))
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+	return nodes
+}
+
+func suggestPaths(suggestions []Suggestion) []string {
+	out := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		out[i] = s.Path
+	}
+	return out
+}
+
+func TestSuggestExactPrefix(t *testing.T) {
+	nodes := suggestTestTree(t)
+	got := suggestPaths(Suggest(nodes, "main", SuggestOptions{Mode: ModeExactPrefix}))
+	want := []string{"src/main.go"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSuggestExactPrefixCaseInsensitive(t *testing.T) {
+	nodes := suggestTestTree(t)
+	got := suggestPaths(Suggest(nodes, "MAIN", SuggestOptions{Mode: ModeExactPrefix, CaseInsensitive: true}))
+	want := []string{"src/main.go"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if got := Suggest(nodes, "MAIN", SuggestOptions{Mode: ModeExactPrefix}); len(got) != 0 {
+		t.Errorf("case-sensitive: want no matches, got %v", got)
+	}
+}
+
+func TestSuggestSubsequence(t *testing.T) {
+	nodes := suggestTestTree(t)
+	// "rcm" only occurs, in order, within "src/main.go" ("[r]" and "[c]" from "src", "[m]" from
+	// "main"); the other candidates either lack an "r", a "c", or an "m" entirely, or (like
+	// "docs/readme.md") don't have them in that order.
+	got := suggestPaths(Suggest(nodes, "rcm", SuggestOptions{Mode: ModeSubsequence}))
+	want := []string{"src/main.go"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSuggestSubsequenceScattered(t *testing.T) {
+	nodes := suggestTestTree(t)
+	// "s" and "h" never appear next to each other in any path, so this only passes if matching
+	// doesn't rely on the trigram index finding a contiguous run of query runes.
+	got := suggestPaths(Suggest(nodes, "sh", SuggestOptions{Mode: ModeSubsequence}))
+	want := []string{"src/utils/helper.go"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSuggestSubsequenceShortestWindow(t *testing.T) {
+	// "aXXbXXXab" has two candidate windows for "ab" as a subsequence: "aXXb" (span 4, found by
+	// always locking onto the first completable "b") and the trailing "ab" itself (span 2, the
+	// true shortest). Ranking must prefer the latter, so this path should sort ahead of "qaXbq",
+	// whose only feasible window ("aXb") has span 3.
+	nodes, err := Tree(mkElems([]elem{"aXXbXXXab", "qaXbq"}))
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+	got := suggestPaths(Suggest(nodes, "ab", SuggestOptions{Mode: ModeSubsequence}))
+	want := []string{"aXXbXXXab", "qaXbq"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSuggestExactPrefixCaseSensitiveMixedCasePath(t *testing.T) {
+	// The trigram index is always built from the lower-cased path, so a case-sensitive query
+	// must still be lower-cased before it's used to look up candidates, even though the match
+	// itself is then done against the original-case path and query.
+	nodes, err := Tree(mkElems([]elem{"src/Main.go", "docs/readme.md"}))
+	if err != nil {
+		t.Fatalf("Tree: unexpected error %q", err)
+	}
+	got := suggestPaths(Suggest(nodes, "Mai", SuggestOptions{Mode: ModeExactPrefix}))
+	want := []string{"src/Main.go"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSuggestLevenshtein(t *testing.T) {
+	nodes := suggestTestTree(t)
+	got := suggestPaths(Suggest(nodes, "main.go", SuggestOptions{Mode: ModeLevenshtein, MaxDistance: 0}))
+	want := []string{"src/main.go", "test/test_main.go"}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestSuggestMaxResults(t *testing.T) {
+	nodes := suggestTestTree(t)
+	got := Suggest(nodes, "main.go", SuggestOptions{Mode: ModeLevenshtein, MaxDistance: 0, MaxResults: 1})
+	if len(got) != 1 {
+		t.Errorf("want 1 result, got %d", len(got))
+	}
+}
+
+func TestIndexReuse(t *testing.T) {
+	idx := NewIndex(suggestTestTree(t))
+	a := suggestPaths(idx.Suggest("main", SuggestOptions{Mode: ModeExactPrefix}))
+	b := suggestPaths(idx.Suggest("readme", SuggestOptions{Mode: ModeExactPrefix}))
+	if want := []string{"src/main.go"}; !reflect.DeepEqual(want, a) {
+		t.Errorf("want %v, got %v", want, a)
+	}
+	if want := []string{"docs/readme.md"}; !reflect.DeepEqual(want, b) {
+		t.Errorf("want %v, got %v", want, b)
+	}
+}